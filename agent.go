@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strings"
+	"sync/atomic"
 
 	"github.com/uber/jaeger-client-go/thrift"
 	"github.com/uber/jaeger-client-go/thrift-gen/agent"
@@ -29,6 +31,18 @@ import (
 // udpPacketMaxLength is the max size of UDP packet we want to send, synced with jaeger-agent.
 const udpPacketMaxLength = 65000
 
+// UDPStats reports cumulative counters for batches the exporter has sent to
+// the Jaeger agent over UDP. Use it to tune BufferMaxCount and the agent's
+// --processor.jaeger-compact.server-max-packet-size.
+type UDPStats struct {
+	// SpansEmitted is the number of spans successfully written to the UDP socket.
+	SpansEmitted uint64
+	// SpansSplit is the number of spans that were re-sent as part of a bisected batch.
+	SpansSplit uint64
+	// SpansDropped is the number of spans discarded because they alone exceeded maxPacketSize.
+	SpansDropped uint64
+}
+
 // agentClientUDP is a UDP client to Jaeger agent that implements gen.Agent interface.
 type agentClientUDP struct {
 	agent.Agent
@@ -38,10 +52,12 @@ type agentClientUDP struct {
 	client        *agent.AgentClient
 	maxPacketSize int                   // max size of datagram in bytes
 	thriftBuffer  *thrift.TMemoryBuffer // buffer used to calculate byte size of a span
+	onDroppedSpan func(span *jaeger.Span)
+	stats         UDPStats
 }
 
 // newAgentClientUDP creates a client that sends spans to Jaeger Agent over UDP.
-func newAgentClientUDP(hostPort string, maxPacketSize int) (*agentClientUDP, error) {
+func newAgentClientUDP(hostPort string, maxPacketSize int, onDroppedSpan func(span *jaeger.Span)) (*agentClientUDP, error) {
 	if maxPacketSize == 0 {
 		maxPacketSize = udpPacketMaxLength
 	}
@@ -68,30 +84,84 @@ func newAgentClientUDP(hostPort string, maxPacketSize int) (*agentClientUDP, err
 		client:        client,
 		maxPacketSize: maxPacketSize,
 		thriftBuffer:  thriftBuffer,
+		onDroppedSpan: onDroppedSpan,
 	}
 	return clientUDP, nil
 }
 
-// EmitBatch implements EmitBatch() of Agent interface.
+// EmitBatch implements EmitBatch() of Agent interface. If the serialized
+// batch does not fit within a single UDP packet, it is recursively bisected
+// and each half is emitted independently; a batch of a single oversize span
+// is dropped and reported via onDroppedSpan rather than failing the whole
+// upload.
 func (a *agentClientUDP) EmitBatch(batch *jaeger.Batch) error {
 	a.thriftBuffer.Reset()
 	// 	a.client.SeqId = 0 // we have no need for distinct SeqIds for our one-way UDP messages
 	if err := a.client.EmitBatch(context.Background(), batch); err != nil {
 		return err
 	}
+	if a.thriftBuffer.Len() <= a.maxPacketSize {
+		if _, err := a.connUDP.Write(a.thriftBuffer.Bytes()); err != nil {
+			return err
+		}
+		atomic.AddUint64(&a.stats.SpansEmitted, uint64(len(batch.Spans)))
+		return nil
+	}
+	return a.emitBatchSplit(batch)
+}
+
+// emitBatchSplit bisects an oversize batch and emits each half, dropping any
+// span that alone still exceeds maxPacketSize.
+func (a *agentClientUDP) emitBatchSplit(batch *jaeger.Batch) error {
+	if len(batch.Spans) <= 1 {
+		atomic.AddUint64(&a.stats.SpansDropped, uint64(len(batch.Spans)))
+		if a.onDroppedSpan != nil {
+			for _, span := range batch.Spans {
+				a.onDroppedSpan(span)
+			}
+		}
+		return nil
+	}
+
+	atomic.AddUint64(&a.stats.SpansSplit, uint64(len(batch.Spans)))
+	mid := len(batch.Spans) / 2
+	halves := [2][]*jaeger.Span{batch.Spans[:mid], batch.Spans[mid:]}
+	var errs []string
+	for _, spans := range halves {
+		half := &jaeger.Batch{Process: batch.Process, Spans: spans}
+		if err := a.EmitBatch(half); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to emit split batch: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Stats returns the cumulative counters for batches sent over this client.
+func (a *agentClientUDP) Stats() UDPStats {
+	return UDPStats{
+		SpansEmitted: atomic.LoadUint64(&a.stats.SpansEmitted),
+		SpansSplit:   atomic.LoadUint64(&a.stats.SpansSplit),
+		SpansDropped: atomic.LoadUint64(&a.stats.SpansDropped),
+	}
+}
+
+// EmitZipkinBatch implements EmitZipkinBatch() of Agent interface.
+func (a *agentClientUDP) EmitZipkinBatch(spans []*zipkincore.Span) error {
+	a.thriftBuffer.Reset()
+	if err := a.client.EmitZipkinBatch(context.Background(), spans); err != nil {
+		return err
+	}
 	if a.thriftBuffer.Len() > a.maxPacketSize {
 		return fmt.Errorf("Data does not fit within one UDP packet; size %d, max %d, spans %d",
-			a.thriftBuffer.Len(), a.maxPacketSize, len(batch.Spans))
+			a.thriftBuffer.Len(), a.maxPacketSize, len(spans))
 	}
 	_, err := a.connUDP.Write(a.thriftBuffer.Bytes())
 	return err
 }
 
-// EmitZipkinBatch implements EmitZipkinBatch() of Agent interface.
-func EmitZipkinBatch(spans []*zipkincore.Span) (err error) {
-	return fmt.Errorf("not implemented")
-}
-
 // Close implements Close() of io.Closer and closes the underlying UDP connection.
 func (a *agentClientUDP) Close() error {
 	return a.connUDP.Close()
@@ -0,0 +1,86 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/uber/jaeger-client-go/thrift-gen/jaeger"
+)
+
+func newTestAgentClientUDP(t *testing.T, maxPacketSize int, onDroppedSpan func(*jaeger.Span)) *agentClientUDP {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	client, err := newAgentClientUDP(conn.LocalAddr().String(), maxPacketSize, onDroppedSpan)
+	if err != nil {
+		t.Fatalf("newAgentClientUDP() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func batchWithSpans(n int, operationName string) *jaeger.Batch {
+	spans := make([]*jaeger.Span, n)
+	for i := range spans {
+		spans[i] = &jaeger.Span{OperationName: operationName}
+	}
+	return &jaeger.Batch{Spans: spans}
+}
+
+func Test_agentClientUDP_EmitBatch_splitsOversizeBatch(t *testing.T) {
+	client := newTestAgentClientUDP(t, 200, nil)
+
+	batch := batchWithSpans(8, strings.Repeat("x", 50))
+	if err := client.EmitBatch(batch); err != nil {
+		t.Fatalf("EmitBatch() error = %v", err)
+	}
+
+	stats := client.Stats()
+	if stats.SpansEmitted != uint64(len(batch.Spans)) {
+		t.Errorf("SpansEmitted = %d, want %d", stats.SpansEmitted, len(batch.Spans))
+	}
+	if stats.SpansSplit == 0 {
+		t.Errorf("SpansSplit = 0, want > 0 for an oversize batch")
+	}
+	if stats.SpansDropped != 0 {
+		t.Errorf("SpansDropped = %d, want 0", stats.SpansDropped)
+	}
+}
+
+func Test_agentClientUDP_EmitBatch_dropsSingleOversizeSpan(t *testing.T) {
+	var dropped []*jaeger.Span
+	client := newTestAgentClientUDP(t, 100, func(s *jaeger.Span) {
+		dropped = append(dropped, s)
+	})
+
+	batch := batchWithSpans(1, strings.Repeat("x", 1000))
+	if err := client.EmitBatch(batch); err != nil {
+		t.Fatalf("EmitBatch() error = %v", err)
+	}
+
+	if len(dropped) != 1 {
+		t.Fatalf("onDroppedSpan called %d times, want 1", len(dropped))
+	}
+	if stats := client.Stats(); stats.SpansDropped != 1 {
+		t.Errorf("SpansDropped = %d, want 1", stats.SpansDropped)
+	}
+}
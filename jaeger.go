@@ -18,6 +18,7 @@ package jaeger // import "github.com/teal-finance/opencensus-go-exporter-jaeger"
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -25,11 +26,16 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"time"
 
+	"contrib.go.opencensus.io/exporter/jaeger/sampling"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/uber/jaeger-client-go/thrift"
 	"github.com/uber/jaeger-client-go/thrift-gen/jaeger"
+	"github.com/uber/jaeger-client-go/thrift-gen/zipkincore"
 	"go.opencensus.io/trace"
 	"google.golang.org/api/support/bundler"
+	"google.golang.org/grpc"
 )
 
 const defaultServiceName = "OpenCensus"
@@ -46,10 +52,39 @@ type Options struct {
 	// For example, http://localhost:14268/api/traces
 	CollectorEndpoint string
 
+	// CollectorGRPCEndpoint is the address of the Jaeger collector's gRPC
+	// endpoint, e.g. localhost:14250. When set, spans are translated to the
+	// Jaeger protobuf model and uploaded over gRPC instead of HTTP Thrift.
+	CollectorGRPCEndpoint string
+
+	// TLSConfig is used to secure the connection to CollectorGRPCEndpoint.
+	// If nil, the connection is established in plaintext.
+	// Only applies to CollectorGRPCEndpoint.
+	TLSConfig *tls.Config
+
+	// Headers are additional headers sent with every batch uploaded to
+	// CollectorGRPCEndpoint, e.g. for authentication.
+	Headers map[string]string
+
+	// CollectorGRPCDialOptions are passed through to grpc.Dial when
+	// connecting to CollectorGRPCEndpoint, allowing callers to configure
+	// keepalive, interceptors, and other dial-time behavior.
+	CollectorGRPCDialOptions []grpc.DialOption
+
 	// AgentEndpoint instructs exporter to send spans to jaeger-agent at this address.
 	// For example, localhost:6831.
 	AgentEndpoint string
 
+	// Format selects the thrift wire format used to report spans to
+	// AgentEndpoint. Defaults to FormatJaegerThrift.
+	Format Format
+
+	// OnDroppedSpan is called, if set, for every span dropped because it
+	// alone exceeds the UDP agent's max packet size. Only applies to
+	// AgentEndpoint.
+	// Optional.
+	OnDroppedSpan func(span *jaeger.Span)
+
 	// OnError is the hook to be called when there is
 	// an error occurred when uploading the stats data.
 	// If no custom hook is set, errors are logged.
@@ -73,25 +108,59 @@ type Options struct {
 
 	// BufferMaxCount defines the total number of traces that can be buffered in memory
 	BufferMaxCount int
+
+	// BufferFlushInterval is the maximum time spans are buffered before being
+	// uploaded. If zero, the bundler's default delay threshold is used.
+	BufferFlushInterval time.Duration
+
+	// EnableRemoteSampling registers a trace.Sampler, backed by Jaeger's
+	// remote sampling manager, as the process-wide default sampler via
+	// trace.ApplyConfig. Process.ServiceName (or ServiceName) is used to
+	// look up this process's strategies.
+	EnableRemoteSampling bool
+
+	// SamplingServerURL is the Jaeger sampling manager endpoint polled when
+	// EnableRemoteSampling is set. Defaults to sampling.DefaultSamplingServerURL.
+	SamplingServerURL string
+
+	// SamplingRefreshInterval is how often sampling strategies are
+	// re-fetched when EnableRemoteSampling is set. Defaults to
+	// sampling.DefaultRefreshInterval.
+	SamplingRefreshInterval time.Duration
+
+	// Metrics receives counters and upload latency for diagnosing exporter
+	// health. Defaults to a HistogramMetrics, recording upload latency in
+	// an HDR histogram that can be read back with Snapshot.
+	Metrics Metrics
 }
 
 // NewExporter returns a trace.Exporter implementation that exports
 // the collected spans to Jaeger.
 func NewExporter(o Options) (*Exporter, error) {
-	if o.Endpoint == "" && o.CollectorEndpoint == "" && o.AgentEndpoint == "" {
+	if o.Endpoint == "" && o.CollectorEndpoint == "" && o.CollectorGRPCEndpoint == "" && o.AgentEndpoint == "" {
 		return nil, errors.New("missing endpoint for Jaeger exporter")
 	}
+	if o.Format == FormatZipkinThrift && (o.AgentEndpoint == "" || o.Endpoint != "" || o.CollectorEndpoint != "" || o.CollectorGRPCEndpoint != "") {
+		return nil, errors.New("FormatZipkinThrift requires AgentEndpoint and is not supported with Endpoint, CollectorEndpoint, or CollectorGRPCEndpoint")
+	}
 
 	var endpoint string
 	var client *agentClientUDP
+	var grpcConn *grpc.ClientConn
+	var grpcClient api_v2.CollectorServiceClient
 	var err error
 	if o.Endpoint != "" {
 		endpoint = o.Endpoint + "/api/traces?format=jaeger.thrift"
 		log.Printf("Endpoint has been deprecated. Please use CollectorEndpoint instead.")
 	} else if o.CollectorEndpoint != "" {
 		endpoint = o.CollectorEndpoint
+	} else if o.CollectorGRPCEndpoint != "" {
+		grpcConn, grpcClient, err = newGRPCCollector(o.CollectorGRPCEndpoint, o.TLSConfig, o.CollectorGRPCDialOptions)
+		if err != nil {
+			return nil, err
+		}
 	} else {
-		client, err = newAgentClientUDP(o.AgentEndpoint, udpPacketMaxLength)
+		client, err = newAgentClientUDP(o.AgentEndpoint, udpPacketMaxLength, o.OnDroppedSpan)
 		if err != nil {
 			return nil, err
 		}
@@ -114,22 +183,63 @@ func NewExporter(o Options) (*Exporter, error) {
 	for i, tag := range o.Process.Tags {
 		tags[i] = attributeToTag(tag.key, tag.value)
 	}
+
+	if o.EnableRemoteSampling {
+		manager, err := sampling.NewManager(sampling.Options{
+			ServiceName:       service,
+			SamplingServerURL: o.SamplingServerURL,
+			RefreshInterval:   o.SamplingRefreshInterval,
+			OnError:           onError,
+		})
+		if err != nil {
+			return nil, err
+		}
+		trace.ApplyConfig(trace.Config{DefaultSampler: manager.Sampler()})
+	}
+
+	metrics := o.Metrics
+	if metrics == nil {
+		metrics = NewHistogramMetrics()
+	}
+
 	e := &Exporter{
 		endpoint:      endpoint,
 		agentEndpoint: o.AgentEndpoint,
 		client:        client,
+		grpcConn:      grpcConn,
+		grpcClient:    grpcClient,
+		headers:       o.Headers,
+		format:        o.Format,
 		username:      o.Username,
 		password:      o.Password,
+		metrics:       metrics,
 		process: &jaeger.Process{
 			ServiceName: service,
 			Tags:        tags,
 		},
 	}
-	bundler := bundler.NewBundler((*jaeger.Span)(nil), func(bundle interface{}) {
+
+	var bundlerItem interface{} = (*jaeger.Span)(nil)
+	handler := func(bundle interface{}) {
 		if err := e.upload(bundle.([]*jaeger.Span)); err != nil {
+			e.metrics.BatchSendErrors()
 			onError(err)
+			return
+		}
+		e.metrics.BatchesSent()
+	}
+	if o.Format == FormatZipkinThrift {
+		bundlerItem = (*zipkincore.Span)(nil)
+		handler = func(bundle interface{}) {
+			if err := e.uploadZipkin(bundle.([]*zipkincore.Span)); err != nil {
+				e.metrics.BatchSendErrors()
+				onError(err)
+				return
+			}
+			e.metrics.BatchesSent()
 		}
-	})
+	}
+	bundler := bundler.NewBundler(bundlerItem, handler)
 
 	// Set BufferedByteLimit with the total number of spans that are permissible to be held in memory.
 	// This needs to be done since the size of messages is always set to 1. Failing to set this would allow
@@ -137,6 +247,9 @@ func NewExporter(o Options) (*Exporter, error) {
 	if o.BufferMaxCount != 0 {
 		bundler.BufferedByteLimit = o.BufferMaxCount
 	}
+	if o.BufferFlushInterval != 0 {
+		bundler.DelayThreshold = o.BufferFlushInterval
+	}
 
 	e.bundler = bundler
 	return e, nil
@@ -182,6 +295,14 @@ type Exporter struct {
 	bundler       *bundler.Bundler
 	client        *agentClientUDP
 
+	grpcConn   *grpc.ClientConn
+	grpcClient api_v2.CollectorServiceClient
+	headers    map[string]string
+
+	format Format
+
+	metrics Metrics
+
 	username, password string
 }
 
@@ -189,9 +310,20 @@ var _ trace.Exporter = (*Exporter)(nil)
 
 // ExportSpan exports a SpanData to Jaeger.
 func (e *Exporter) ExportSpan(data *trace.SpanData) {
-	if data.IsSampled() {
-		e.bundler.Add(spanDataToThrift(data), 1)
+	if !data.IsSampled() {
+		return
 	}
+	var item interface{}
+	if e.format == FormatZipkinThrift {
+		item = spanDataToZipkin(data, e.process.ServiceName)
+	} else {
+		item = spanDataToThrift(data)
+	}
+	if err := e.bundler.Add(item, 1); err != nil {
+		e.metrics.SpansDropped()
+		return
+	}
+	e.metrics.SpansSubmitted()
 }
 
 // As per the OpenCensus Status code mapping in
@@ -314,11 +446,24 @@ func (e *Exporter) Flush() {
 	e.bundler.Flush()
 }
 
+// UDPStats returns the cumulative counters for batches sent to the Jaeger
+// agent over UDP. It returns the zero value if the exporter was not
+// configured with AgentEndpoint.
+func (e *Exporter) UDPStats() UDPStats {
+	if e.client == nil {
+		return UDPStats{}
+	}
+	return e.client.Stats()
+}
+
 func (e *Exporter) upload(spans []*jaeger.Span) error {
 	batch := &jaeger.Batch{
 		Spans:   spans,
 		Process: e.process,
 	}
+	if e.grpcClient != nil {
+		return e.uploadGRPC(batch)
+	}
 	if e.endpoint != "" {
 		return e.uploadCollector(batch)
 	}
@@ -326,10 +471,27 @@ func (e *Exporter) upload(spans []*jaeger.Span) error {
 }
 
 func (e *Exporter) uploadAgent(batch *jaeger.Batch) error {
-	return e.client.EmitBatch(batch)
+	start := time.Now()
+	err := e.client.EmitBatch(batch)
+	e.metrics.UploadLatencyNanos(time.Since(start).Nanoseconds())
+	return err
+}
+
+func (e *Exporter) uploadZipkin(spans []*zipkincore.Span) error {
+	start := time.Now()
+	err := e.client.EmitZipkinBatch(spans)
+	e.metrics.UploadLatencyNanos(time.Since(start).Nanoseconds())
+	return err
 }
 
 func (e *Exporter) uploadCollector(batch *jaeger.Batch) error {
+	start := time.Now()
+	err := e.doUploadCollector(batch)
+	e.metrics.UploadLatencyNanos(time.Since(start).Nanoseconds())
+	return err
+}
+
+func (e *Exporter) doUploadCollector(batch *jaeger.Batch) error {
 	body, err := serialize(batch)
 	if err != nil {
 		return err
@@ -0,0 +1,158 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variable names recognized by Options.LoadFromEnv, matching the
+// JAEGER_* variables used by jaeger-client-go so that a service configured
+// for the Jaeger client also works with this exporter.
+const (
+	envServiceName           = "JAEGER_SERVICE_NAME"
+	envTags                  = "JAEGER_TAGS"
+	envAgentHost             = "JAEGER_AGENT_HOST"
+	envAgentPort             = "JAEGER_AGENT_PORT"
+	envEndpoint              = "JAEGER_ENDPOINT"
+	envUser                  = "JAEGER_USER"
+	envPassword              = "JAEGER_PASSWORD"
+	envReporterMaxQueueSize  = "JAEGER_REPORTER_MAX_QUEUE_SIZE"
+	envReporterFlushInterval = "JAEGER_REPORTER_FLUSH_INTERVAL"
+)
+
+// NewExporterFromEnv is like NewExporter, but fills the zero-valued fields of
+// o from the standard JAEGER_* environment variables before constructing the
+// Exporter. See Options.LoadFromEnv for the list of variables it honors.
+func NewExporterFromEnv(o Options) (*Exporter, error) {
+	if err := o.LoadFromEnv(); err != nil {
+		return nil, err
+	}
+	return NewExporter(o)
+}
+
+// LoadFromEnv fills any zero-valued fields of o from the standard JAEGER_*
+// environment variables used by jaeger-client-go:
+//
+//   - JAEGER_SERVICE_NAME        -> Process.ServiceName
+//   - JAEGER_TAGS                -> Process.Tags
+//   - JAEGER_AGENT_HOST/_PORT    -> AgentEndpoint
+//   - JAEGER_ENDPOINT            -> CollectorEndpoint
+//   - JAEGER_USER/_PASSWORD      -> Username/Password
+//   - JAEGER_REPORTER_MAX_QUEUE_SIZE  -> BufferMaxCount
+//   - JAEGER_REPORTER_FLUSH_INTERVAL  -> BufferFlushInterval
+//
+// Fields that are already set on o take precedence over the environment;
+// LoadFromEnv never overwrites a non-zero value. Malformed values are
+// reported through the returned error rather than silently ignored.
+func (o *Options) LoadFromEnv() error {
+	if o.Process.ServiceName == "" {
+		if v := os.Getenv(envServiceName); v != "" {
+			o.Process.ServiceName = v
+		}
+	}
+	if len(o.Process.Tags) == 0 {
+		if v := os.Getenv(envTags); v != "" {
+			tags, err := tagsFromEnv(v)
+			if err != nil {
+				return err
+			}
+			o.Process.Tags = tags
+		}
+	}
+	if o.AgentEndpoint == "" {
+		host := os.Getenv(envAgentHost)
+		port := os.Getenv(envAgentPort)
+		if host != "" || port != "" {
+			if host == "" {
+				host = "localhost"
+			}
+			if port == "" {
+				port = "6831"
+			}
+			o.AgentEndpoint = net.JoinHostPort(host, port)
+		}
+	}
+	if o.CollectorEndpoint == "" {
+		if v := os.Getenv(envEndpoint); v != "" {
+			o.CollectorEndpoint = v
+		}
+	}
+	if o.Username == "" {
+		if v := os.Getenv(envUser); v != "" {
+			o.Username = v
+		}
+	}
+	if o.Password == "" {
+		if v := os.Getenv(envPassword); v != "" {
+			o.Password = v
+		}
+	}
+	if o.BufferMaxCount == 0 {
+		if v := os.Getenv(envReporterMaxQueueSize); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return envParseError(envReporterMaxQueueSize, v, err)
+			}
+			o.BufferMaxCount = n
+		}
+	}
+	if o.BufferFlushInterval == 0 {
+		if v := os.Getenv(envReporterFlushInterval); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return envParseError(envReporterFlushInterval, v, err)
+			}
+			o.BufferFlushInterval = d
+		}
+	}
+	return nil
+}
+
+// tagsFromEnv parses JAEGER_TAGS: a comma-separated list of key=value pairs,
+// where value may reference another environment variable via the
+// ${ENV_VAR:default} notation.
+func tagsFromEnv(s string) ([]Tag, error) {
+	pairs := strings.Split(s, ",")
+	tags := make([]Tag, 0, len(pairs))
+	for _, p := range pairs {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid %s entry %q, expected key=value", envTags, p)
+		}
+		k, v := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if strings.HasPrefix(v, "${") && strings.HasSuffix(v, "}") {
+			ed := strings.SplitN(v[2:len(v)-1], ":", 2)
+			envVar, def := ed[0], ""
+			if len(ed) == 2 {
+				def = ed[1]
+			}
+			if v = os.Getenv(envVar); v == "" {
+				v = def
+			}
+		}
+		tags = append(tags, StringTag(k, v))
+	}
+	return tags, nil
+}
+
+func envParseError(name, value string, err error) error {
+	return fmt.Errorf("cannot parse env var %s=%q: %w", name, value, err)
+}
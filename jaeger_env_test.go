@@ -0,0 +1,74 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOptions_LoadFromEnv(t *testing.T) {
+	t.Setenv(envServiceName, "my-service")
+	t.Setenv(envTags, "a=b, c=${JAEGER_ENV_TEST_VAR:fallback}")
+	t.Setenv(envAgentHost, "agent.local")
+	t.Setenv(envAgentPort, "6832")
+	t.Setenv(envReporterMaxQueueSize, "100")
+	t.Setenv(envReporterFlushInterval, "2s")
+
+	var o Options
+	if err := o.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() error = %v", err)
+	}
+
+	if got, want := o.Process.ServiceName, "my-service"; got != want {
+		t.Errorf("ServiceName = %q, want %q", got, want)
+	}
+	if got, want := o.AgentEndpoint, "agent.local:6832"; got != want {
+		t.Errorf("AgentEndpoint = %q, want %q", got, want)
+	}
+	if got, want := o.BufferMaxCount, 100; got != want {
+		t.Errorf("BufferMaxCount = %d, want %d", got, want)
+	}
+	if got, want := o.BufferFlushInterval, 2*time.Second; got != want {
+		t.Errorf("BufferFlushInterval = %v, want %v", got, want)
+	}
+	if len(o.Process.Tags) != 2 {
+		t.Fatalf("Tags = %v, want 2 entries", o.Process.Tags)
+	}
+	if got, want := o.Process.Tags[1].value, "fallback"; got != want {
+		t.Errorf("Tags[1].value = %q, want %q", got, want)
+	}
+}
+
+func TestOptions_LoadFromEnv_doesNotOverrideSetFields(t *testing.T) {
+	t.Setenv(envServiceName, "from-env")
+
+	o := Options{Process: Process{ServiceName: "explicit"}}
+	if err := o.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() error = %v", err)
+	}
+	if got, want := o.Process.ServiceName, "explicit"; got != want {
+		t.Errorf("ServiceName = %q, want %q", got, want)
+	}
+}
+
+func TestOptions_LoadFromEnv_invalidDuration(t *testing.T) {
+	t.Setenv(envReporterFlushInterval, "not-a-duration")
+
+	var o Options
+	if err := o.LoadFromEnv(); err == nil {
+		t.Error("LoadFromEnv() error = nil, want error for invalid duration")
+	}
+}
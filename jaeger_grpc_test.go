@@ -0,0 +1,50 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"testing"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/uber/jaeger-client-go/thrift-gen/jaeger"
+)
+
+func Test_modelSpanFromThrift_parentSpanID(t *testing.T) {
+	s := &jaeger.Span{
+		TraceIdHigh:  1,
+		TraceIdLow:   2,
+		SpanId:       3,
+		ParentSpanId: 4,
+	}
+	span := modelSpanFromThrift(s)
+
+	parentID := span.ParentSpanID()
+	if want := model.NewSpanID(4); parentID != want {
+		t.Errorf("ParentSpanID() = %v, want %v", parentID, want)
+	}
+}
+
+func Test_modelSpanFromThrift_noParentSpanID(t *testing.T) {
+	s := &jaeger.Span{
+		TraceIdHigh: 1,
+		TraceIdLow:  2,
+		SpanId:      3,
+	}
+	span := modelSpanFromThrift(s)
+
+	if len(span.References) != 0 {
+		t.Errorf("References = %v, want none for a root span", span.References)
+	}
+}
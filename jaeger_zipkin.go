@@ -0,0 +1,138 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/uber/jaeger-client-go/thrift-gen/zipkincore"
+	"go.opencensus.io/trace"
+)
+
+// Format selects the thrift wire format used to report spans to the Jaeger
+// agent.
+type Format int
+
+const (
+	// FormatJaegerThrift reports spans using the native Jaeger thrift model.
+	// This is the default.
+	FormatJaegerThrift Format = iota
+
+	// FormatZipkinThrift reports spans using the Zipkin thrift model, for
+	// agents that only accept Zipkin-formatted batches. Only supported with
+	// AgentEndpoint; NewExporter rejects it combined with Endpoint,
+	// CollectorEndpoint, or CollectorGRPCEndpoint.
+	FormatZipkinThrift
+)
+
+func spanDataToZipkin(data *trace.SpanData, serviceName string) *zipkincore.Span {
+	endpoint := &zipkincore.Endpoint{ServiceName: serviceName}
+
+	traceIDHigh := bytesToInt64(data.TraceID[0:8])
+	span := &zipkincore.Span{
+		TraceID:           bytesToInt64(data.TraceID[8:16]),
+		TraceIDHigh:       &traceIDHigh,
+		ID:                bytesToInt64(data.SpanID[:]),
+		Name:              data.Name,
+		Timestamp:         int64Ptr(data.StartTime.UnixNano() / 1000),
+		Duration:          int64Ptr(data.EndTime.Sub(data.StartTime).Nanoseconds() / 1000),
+		Annotations:       kindAnnotations(data, endpoint),
+		BinaryAnnotations: make([]*zipkincore.BinaryAnnotation, 0, len(data.Attributes)),
+	}
+	if data.ParentSpanID != (trace.SpanID{}) {
+		parentID := bytesToInt64(data.ParentSpanID[:])
+		span.ParentID = &parentID
+	}
+
+	for k, v := range data.Attributes {
+		if ba := attributeToBinaryAnnotation(k, v, endpoint); ba != nil {
+			span.BinaryAnnotations = append(span.BinaryAnnotations, ba)
+		}
+	}
+	for _, a := range data.Annotations {
+		span.Annotations = append(span.Annotations, &zipkincore.Annotation{
+			Timestamp: a.Time.UnixNano() / 1000,
+			Value:     a.Message,
+			Host:      endpoint,
+		})
+	}
+	return span
+}
+
+// kindAnnotations returns the cs/sr/cr/ss Zipkin annotation pair that marks
+// the RPC role of the span, based on its OpenCensus SpanKind.
+func kindAnnotations(data *trace.SpanData, endpoint *zipkincore.Endpoint) []*zipkincore.Annotation {
+	start := data.StartTime.UnixNano() / 1000
+	end := data.EndTime.UnixNano() / 1000
+	switch data.SpanKind {
+	case trace.SpanKindClient:
+		return []*zipkincore.Annotation{
+			{Timestamp: start, Value: zipkincore.CLIENT_SEND, Host: endpoint},
+			{Timestamp: end, Value: zipkincore.CLIENT_RECV, Host: endpoint},
+		}
+	case trace.SpanKindServer:
+		return []*zipkincore.Annotation{
+			{Timestamp: start, Value: zipkincore.SERVER_RECV, Host: endpoint},
+			{Timestamp: end, Value: zipkincore.SERVER_SEND, Host: endpoint},
+		}
+	}
+	return nil
+}
+
+func attributeToBinaryAnnotation(key string, a interface{}, endpoint *zipkincore.Endpoint) *zipkincore.BinaryAnnotation {
+	ba := &zipkincore.BinaryAnnotation{Key: key, Host: endpoint}
+	switch value := a.(type) {
+	case bool:
+		ba.AnnotationType = zipkincore.AnnotationType_BOOL
+		if value {
+			ba.Value = []byte{1}
+		} else {
+			ba.Value = []byte{0}
+		}
+	case string:
+		ba.AnnotationType = zipkincore.AnnotationType_STRING
+		ba.Value = []byte(value)
+	case int64:
+		ba.AnnotationType = zipkincore.AnnotationType_I64
+		ba.Value = int64ToBytes(value)
+	case int32:
+		ba.AnnotationType = zipkincore.AnnotationType_I32
+		ba.Value = int32ToBytes(value)
+	case float64:
+		ba.AnnotationType = zipkincore.AnnotationType_DOUBLE
+		ba.Value = make([]byte, 8)
+		binary.BigEndian.PutUint64(ba.Value, math.Float64bits(value))
+	default:
+		return nil
+	}
+	return ba
+}
+
+func int64ToBytes(v int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(v))
+	return b
+}
+
+func int32ToBytes(v int32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(v))
+	return b
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
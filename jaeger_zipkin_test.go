@@ -0,0 +1,108 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/uber/jaeger-client-go/thrift-gen/zipkincore"
+	"go.opencensus.io/trace"
+)
+
+func Test_spanDataToZipkin(t *testing.T) {
+	now := time.Now()
+	data := &trace.SpanData{
+		SpanContext: trace.SpanContext{
+			TraceID: trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			SpanID:  trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		Name:      "/foo",
+		SpanKind:  trace.SpanKindClient,
+		StartTime: now,
+		EndTime:   now.Add(time.Second),
+		Attributes: map[string]interface{}{
+			"key": "value",
+		},
+	}
+
+	got := spanDataToZipkin(data, "my-service")
+
+	if got.Name != "/foo" {
+		t.Errorf("Name = %q, want %q", got.Name, "/foo")
+	}
+	if got.TraceID != 651345242494996240 {
+		t.Errorf("TraceID = %d, want %d", got.TraceID, 651345242494996240)
+	}
+	if len(got.BinaryAnnotations) != 1 || got.BinaryAnnotations[0].Key != "key" {
+		t.Errorf("BinaryAnnotations = %+v, want a single %q entry", got.BinaryAnnotations, "key")
+	}
+
+	var sawCS, sawCR bool
+	for _, a := range got.Annotations {
+		switch a.Value {
+		case zipkincore.CLIENT_SEND:
+			sawCS = true
+		case zipkincore.CLIENT_RECV:
+			sawCR = true
+		}
+	}
+	if !sawCS || !sawCR {
+		t.Errorf("Annotations = %+v, want both cs and cr for a client span", got.Annotations)
+	}
+}
+
+func Test_attributeToBinaryAnnotation_int32(t *testing.T) {
+	ba := attributeToBinaryAnnotation("key", int32(42), nil)
+
+	if ba.AnnotationType != zipkincore.AnnotationType_I32 {
+		t.Fatalf("AnnotationType = %v, want I32", ba.AnnotationType)
+	}
+	if len(ba.Value) != 4 {
+		t.Fatalf("Value = %v (len %d), want 4 bytes for an I32 annotation", ba.Value, len(ba.Value))
+	}
+	if got := int32(binary.BigEndian.Uint32(ba.Value)); got != 42 {
+		t.Errorf("decoded value = %d, want 42", got)
+	}
+}
+
+func TestNewExporter_zipkinRequiresAgentEndpoint(t *testing.T) {
+	tests := []struct {
+		name string
+		o    Options
+	}{
+		{"no endpoint at all", Options{Format: FormatZipkinThrift}},
+		{"collector endpoint", Options{Format: FormatZipkinThrift, CollectorEndpoint: "http://localhost:14268/api/traces"}},
+		{"grpc collector endpoint", Options{Format: FormatZipkinThrift, CollectorGRPCEndpoint: "localhost:14250"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewExporter(tt.o); err == nil {
+				t.Error("NewExporter() error = nil, want an error for FormatZipkinThrift without AgentEndpoint")
+			}
+		})
+	}
+}
+
+func TestNewExporter_zipkinWithAgentEndpoint(t *testing.T) {
+	exp, err := NewExporter(Options{Format: FormatZipkinThrift, AgentEndpoint: "localhost:6831"})
+	if err != nil {
+		t.Fatalf("NewExporter() error = %v", err)
+	}
+	if exp.client == nil {
+		t.Error("client = nil, want a non-nil agentClientUDP for FormatZipkinThrift with AgentEndpoint")
+	}
+}
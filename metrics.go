@@ -0,0 +1,121 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// Metrics receives counters and upload latency from the exporter, giving
+// operators visibility into whether spans are being uploaded, dropped by the
+// bundler, or piling up. ExportSpan, the bundler's upload handler, and the
+// upload* methods all call it.
+type Metrics interface {
+	// SpansSubmitted is called for every span accepted by the bundler.
+	SpansSubmitted()
+	// SpansDropped is called for every span the bundler refused, e.g.
+	// because BufferMaxCount was exceeded.
+	SpansDropped()
+	// BatchesSent is called for every batch successfully uploaded.
+	BatchesSent()
+	// BatchSendErrors is called for every batch upload that failed.
+	BatchSendErrors()
+	// UploadLatencyNanos reports the wall-clock duration of one batch
+	// upload, in nanoseconds.
+	UploadLatencyNanos(nanos int64)
+}
+
+const (
+	uploadLatencyMinNanos           = 0
+	uploadLatencyMaxNanos           = int64(time.Minute)
+	uploadLatencySignificantFigures = 3
+)
+
+// HistogramMetrics is the default Metrics implementation, used when
+// Options.Metrics is unset. It keeps plain counters for spans and batches,
+// and records upload latency in an HDR histogram spanning 0 to 60s with 3
+// significant figures, so that percentiles can be read back with Snapshot.
+type HistogramMetrics struct {
+	spansSubmitted  uint64
+	spansDropped    uint64
+	batchesSent     uint64
+	batchSendErrors uint64
+
+	mu        sync.Mutex
+	histogram *hdrhistogram.Histogram
+}
+
+// NewHistogramMetrics returns a HistogramMetrics ready for use.
+func NewHistogramMetrics() *HistogramMetrics {
+	return &HistogramMetrics{
+		histogram: hdrhistogram.New(uploadLatencyMinNanos, uploadLatencyMaxNanos, uploadLatencySignificantFigures),
+	}
+}
+
+var _ Metrics = (*HistogramMetrics)(nil)
+
+func (m *HistogramMetrics) SpansSubmitted()  { atomic.AddUint64(&m.spansSubmitted, 1) }
+func (m *HistogramMetrics) SpansDropped()    { atomic.AddUint64(&m.spansDropped, 1) }
+func (m *HistogramMetrics) BatchesSent()     { atomic.AddUint64(&m.batchesSent, 1) }
+func (m *HistogramMetrics) BatchSendErrors() { atomic.AddUint64(&m.batchSendErrors, 1) }
+
+// UploadLatencyNanos records nanos in the upload latency histogram. Values
+// outside [0, 60s] are clamped to the nearest bound.
+func (m *HistogramMetrics) UploadLatencyNanos(nanos int64) {
+	if nanos < uploadLatencyMinNanos {
+		nanos = uploadLatencyMinNanos
+	} else if nanos > uploadLatencyMaxNanos {
+		nanos = uploadLatencyMaxNanos
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_ = m.histogram.RecordValue(nanos)
+}
+
+// MetricsSnapshot is a point-in-time copy of HistogramMetrics' counters and
+// upload latency percentiles.
+type MetricsSnapshot struct {
+	SpansSubmitted  uint64
+	SpansDropped    uint64
+	BatchesSent     uint64
+	BatchSendErrors uint64
+
+	UploadLatencyP50 time.Duration
+	UploadLatencyP95 time.Duration
+	UploadLatencyP99 time.Duration
+}
+
+// Snapshot returns the current counters and upload latency percentiles.
+func (m *HistogramMetrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	p50 := m.histogram.ValueAtPercentile(50)
+	p95 := m.histogram.ValueAtPercentile(95)
+	p99 := m.histogram.ValueAtPercentile(99)
+	m.mu.Unlock()
+
+	return MetricsSnapshot{
+		SpansSubmitted:   atomic.LoadUint64(&m.spansSubmitted),
+		SpansDropped:     atomic.LoadUint64(&m.spansDropped),
+		BatchesSent:      atomic.LoadUint64(&m.batchesSent),
+		BatchSendErrors:  atomic.LoadUint64(&m.batchSendErrors),
+		UploadLatencyP50: time.Duration(p50),
+		UploadLatencyP95: time.Duration(p95),
+		UploadLatencyP99: time.Duration(p99),
+	}
+}
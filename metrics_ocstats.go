@@ -0,0 +1,98 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+// Measures recorded by OCStatsMetrics.
+var (
+	MeasureSpansSubmitted  = stats.Int64("opencensus.io/exporter/jaeger/spans_submitted", "Number of spans submitted to the Jaeger exporter for upload", stats.UnitDimensionless)
+	MeasureSpansDropped    = stats.Int64("opencensus.io/exporter/jaeger/spans_dropped", "Number of spans dropped by the Jaeger exporter's bundler", stats.UnitDimensionless)
+	MeasureBatchesSent     = stats.Int64("opencensus.io/exporter/jaeger/batches_sent", "Number of batches successfully uploaded to Jaeger", stats.UnitDimensionless)
+	MeasureBatchSendErrors = stats.Int64("opencensus.io/exporter/jaeger/batch_send_errors", "Number of batch uploads to Jaeger that failed", stats.UnitDimensionless)
+	MeasureUploadLatencyMs = stats.Float64("opencensus.io/exporter/jaeger/upload_latency", "Latency of batch uploads to Jaeger", stats.UnitMilliseconds)
+)
+
+// Default views for the measures above. Applications must register the
+// views they want with view.Register; they are not registered automatically.
+var (
+	ViewSpansSubmitted = &view.View{
+		Name:        "opencensus.io/exporter/jaeger/spans_submitted",
+		Measure:     MeasureSpansSubmitted,
+		Description: "Number of spans submitted to the Jaeger exporter for upload",
+		Aggregation: view.Count(),
+	}
+	ViewSpansDropped = &view.View{
+		Name:        "opencensus.io/exporter/jaeger/spans_dropped",
+		Measure:     MeasureSpansDropped,
+		Description: "Number of spans dropped by the Jaeger exporter's bundler",
+		Aggregation: view.Count(),
+	}
+	ViewBatchesSent = &view.View{
+		Name:        "opencensus.io/exporter/jaeger/batches_sent",
+		Measure:     MeasureBatchesSent,
+		Description: "Number of batches successfully uploaded to Jaeger",
+		Aggregation: view.Count(),
+	}
+	ViewBatchSendErrors = &view.View{
+		Name:        "opencensus.io/exporter/jaeger/batch_send_errors",
+		Measure:     MeasureBatchSendErrors,
+		Description: "Number of batch uploads to Jaeger that failed",
+		Aggregation: view.Count(),
+	}
+	ViewUploadLatency = &view.View{
+		Name:        "opencensus.io/exporter/jaeger/upload_latency",
+		Measure:     MeasureUploadLatencyMs,
+		Description: "Distribution of Jaeger batch upload latency, in milliseconds",
+		Aggregation: view.Distribution(0, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000),
+	}
+)
+
+// DefaultViews are all the views defined by this package, for convenience
+// when registering all of them with view.Register.
+var DefaultViews = []*view.View{
+	ViewSpansSubmitted,
+	ViewSpansDropped,
+	ViewBatchesSent,
+	ViewBatchSendErrors,
+	ViewUploadLatency,
+}
+
+// OCStatsMetrics is a Metrics implementation that records to OpenCensus
+// stats, so that applications already scraping OC stats get Jaeger exporter
+// health for free. Register the views in DefaultViews (or a subset of them)
+// with view.Register to collect the recorded measurements.
+type OCStatsMetrics struct{}
+
+var _ Metrics = OCStatsMetrics{}
+
+func (OCStatsMetrics) SpansSubmitted() {
+	stats.Record(context.Background(), MeasureSpansSubmitted.M(1))
+}
+func (OCStatsMetrics) SpansDropped() { stats.Record(context.Background(), MeasureSpansDropped.M(1)) }
+func (OCStatsMetrics) BatchesSent()  { stats.Record(context.Background(), MeasureBatchesSent.M(1)) }
+func (OCStatsMetrics) BatchSendErrors() {
+	stats.Record(context.Background(), MeasureBatchSendErrors.M(1))
+}
+
+func (OCStatsMetrics) UploadLatencyNanos(nanos int64) {
+	stats.Record(context.Background(), MeasureUploadLatencyMs.M(float64(nanos)/float64(time.Millisecond)))
+}
@@ -0,0 +1,63 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramMetrics_Snapshot(t *testing.T) {
+	m := NewHistogramMetrics()
+
+	m.SpansSubmitted()
+	m.SpansSubmitted()
+	m.SpansDropped()
+	m.BatchesSent()
+	m.BatchSendErrors()
+	m.UploadLatencyNanos(int64(10 * time.Millisecond))
+	m.UploadLatencyNanos(int64(20 * time.Millisecond))
+
+	snap := m.Snapshot()
+	if snap.SpansSubmitted != 2 {
+		t.Errorf("SpansSubmitted = %d, want 2", snap.SpansSubmitted)
+	}
+	if snap.SpansDropped != 1 {
+		t.Errorf("SpansDropped = %d, want 1", snap.SpansDropped)
+	}
+	if snap.BatchesSent != 1 {
+		t.Errorf("BatchesSent = %d, want 1", snap.BatchesSent)
+	}
+	if snap.BatchSendErrors != 1 {
+		t.Errorf("BatchSendErrors = %d, want 1", snap.BatchSendErrors)
+	}
+	if snap.UploadLatencyP50 < 10*time.Millisecond || snap.UploadLatencyP50 > 20*time.Millisecond {
+		t.Errorf("UploadLatencyP50 = %s, want between 10ms and 20ms", snap.UploadLatencyP50)
+	}
+}
+
+func TestHistogramMetrics_UploadLatencyNanosClampsOutOfRange(t *testing.T) {
+	m := NewHistogramMetrics()
+
+	m.UploadLatencyNanos(-1)
+	m.UploadLatencyNanos(int64(time.Hour))
+
+	snap := m.Snapshot()
+	// The histogram's bucket boundaries round up, so allow a small margin
+	// above the configured maximum rather than requiring an exact bound.
+	if max := time.Duration(uploadLatencyMaxNanos) + time.Second; snap.UploadLatencyP99 > max {
+		t.Errorf("UploadLatencyP99 = %s, want <= %s", snap.UploadLatencyP99, max)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a leaky-bucket rate limiter: a credit balance, capped at
+// maxBalance, is replenished at creditsPerSecond and drawn down by one
+// credit per allowed item. It guarantees at most creditsPerSecond items are
+// allowed per second on average, while tolerating short bursts up to
+// maxBalance.
+type rateLimiter struct {
+	mu sync.Mutex
+
+	creditsPerSecond float64
+	maxBalance       float64
+	balance          float64
+	lastTick         time.Time
+
+	now func() time.Time // overridden in tests to inject a deterministic clock
+}
+
+func newRateLimiter(creditsPerSecond, maxBalance float64) *rateLimiter {
+	return &rateLimiter{
+		creditsPerSecond: creditsPerSecond,
+		maxBalance:       maxBalance,
+		balance:          maxBalance,
+		lastTick:         time.Now(),
+		now:              time.Now,
+	}
+}
+
+// allow reports whether there is enough balance for one item, consuming a
+// credit if so.
+func (r *rateLimiter) allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.now()
+	elapsed := current.Sub(r.lastTick).Seconds()
+	r.lastTick = current
+
+	r.balance += elapsed * r.creditsPerSecond
+	if r.balance > r.maxBalance {
+		r.balance = r.maxBalance
+	}
+	if r.balance < 1 {
+		return false
+	}
+	r.balance--
+	return true
+}
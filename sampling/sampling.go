@@ -0,0 +1,298 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sampling implements a trace.Sampler backed by Jaeger's remote
+// sampling manager, supporting adaptive, per-operation sampling strategies.
+package sampling
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/uber/jaeger-client-go/thrift-gen/sampling"
+	"go.opencensus.io/trace"
+)
+
+// DefaultSamplingServerURL is the default Jaeger sampling manager endpoint.
+const DefaultSamplingServerURL = "http://localhost:5778/sampling"
+
+// DefaultRefreshInterval is how often strategies are re-fetched when
+// Options.RefreshInterval is unset.
+const DefaultRefreshInterval = time.Minute
+
+// defaultMaxOperations bounds the number of operations tracked with their
+// own sampler and rate limiter, to guard against unbounded memory growth
+// from high-cardinality operation names.
+const defaultMaxOperations = 2000
+
+// defaultProbability is used for operations not yet known to the manager.
+const defaultProbability = 0.001
+
+// Options configure a Manager.
+type Options struct {
+	// ServiceName identifies this process to the sampling manager. Required.
+	ServiceName string
+
+	// SamplingServerURL is the Jaeger sampling manager endpoint, e.g.
+	// http://localhost:5778/sampling. Defaults to DefaultSamplingServerURL.
+	SamplingServerURL string
+
+	// RefreshInterval is how often strategies are re-fetched. Defaults to
+	// DefaultRefreshInterval.
+	RefreshInterval time.Duration
+
+	// MaxOperations caps the number of distinct operations tracked with
+	// their own sampler and rate limiter. Defaults to 2000.
+	MaxOperations int
+
+	// Client is used to fetch strategies. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// OnError is called, if set, when a strategy refresh fails. Failures do
+	// not affect the currently active strategies.
+	OnError func(err error)
+}
+
+// Manager periodically polls a Jaeger sampling manager for per-operation
+// sampling strategies and exposes them as an OpenCensus trace.Sampler
+// implementing Jaeger's guaranteed-throughput scheme: an operation is
+// sampled if either its probabilistic sampler or its leaky-bucket rate
+// limiter fires; operations unseen by the manager fall back to the default
+// probability.
+type Manager struct {
+	serviceName   string
+	url           string
+	client        *http.Client
+	onError       func(err error)
+	maxOperations int
+
+	ticker *time.Ticker
+	done   chan struct{}
+
+	strategies atomic.Value // *perOperationStrategies
+}
+
+// NewManager creates a Manager and performs an initial, synchronous fetch of
+// strategies before starting the background refresh loop.
+func NewManager(o Options) (*Manager, error) {
+	if o.ServiceName == "" {
+		return nil, fmt.Errorf("sampling: ServiceName is required")
+	}
+	serverURL := o.SamplingServerURL
+	if serverURL == "" {
+		serverURL = DefaultSamplingServerURL
+	}
+	refreshInterval := o.RefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultRefreshInterval
+	}
+	maxOperations := o.MaxOperations
+	if maxOperations <= 0 {
+		maxOperations = defaultMaxOperations
+	}
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	m := &Manager{
+		serviceName:   o.ServiceName,
+		url:           serverURL,
+		client:        client,
+		onError:       o.OnError,
+		maxOperations: maxOperations,
+		done:          make(chan struct{}),
+	}
+	m.strategies.Store(newPerOperationStrategies())
+
+	if err := m.refresh(); err != nil && m.onError != nil {
+		m.onError(err)
+	}
+
+	m.ticker = time.NewTicker(refreshInterval)
+	go m.pollLoop()
+	return m, nil
+}
+
+func (m *Manager) pollLoop() {
+	for {
+		select {
+		case <-m.ticker.C:
+			if err := m.refresh(); err != nil && m.onError != nil {
+				m.onError(err)
+			}
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// Close stops the background refresh loop. The Sampler returned by Sampler
+// continues to use the last strategies fetched before Close was called.
+func (m *Manager) Close() {
+	m.ticker.Stop()
+	close(m.done)
+}
+
+func (m *Manager) refresh() error {
+	resp, err := m.fetch()
+	if err != nil {
+		return err
+	}
+	m.strategies.Load().(*perOperationStrategies).update(resp, m.maxOperations)
+	return nil
+}
+
+func (m *Manager) fetch() (*sampling.SamplingStrategyResponse, error) {
+	u, err := url.Parse(m.url)
+	if err != nil {
+		return nil, fmt.Errorf("sampling: invalid SamplingServerURL %q: %w", m.url, err)
+	}
+	q := u.Query()
+	q.Set("service", m.serviceName)
+	u.RawQuery = q.Encode()
+
+	resp, err := m.client.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("sampling: failed to fetch strategies: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sampling: unexpected status %d fetching strategies from %s", resp.StatusCode, u.String())
+	}
+	var strategy sampling.SamplingStrategyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&strategy); err != nil {
+		return nil, fmt.Errorf("sampling: failed to decode strategy response: %w", err)
+	}
+	return &strategy, nil
+}
+
+// Sampler returns a trace.Sampler backed by the most recently fetched
+// strategies. It is meant to be passed to trace.ApplyConfig and remains
+// valid as strategies are refreshed in the background.
+func (m *Manager) Sampler() trace.Sampler {
+	return func(p trace.SamplingParameters) trace.SamplingDecision {
+		current := m.strategies.Load().(*perOperationStrategies)
+		return trace.SamplingDecision{Sample: current.sample(p)}
+	}
+}
+
+// operationStrategy holds the guaranteed-throughput sampler for a single
+// operation: a probabilistic sampler backed by a leaky-bucket rate limiter
+// that guarantees at least one trace per lowerBound seconds.
+type operationStrategy struct {
+	probability float64
+	limiter     *rateLimiter
+}
+
+// perOperationStrategies is the immutable-after-construction snapshot of the
+// strategies currently in effect, swapped in wholesale by update.
+type perOperationStrategies struct {
+	mu                 sync.RWMutex
+	defaultProbability float64
+	operations         map[string]*operationStrategy
+}
+
+func newPerOperationStrategies() *perOperationStrategies {
+	return &perOperationStrategies{
+		defaultProbability: defaultProbability,
+		operations:         make(map[string]*operationStrategy),
+	}
+}
+
+func (s *perOperationStrategies) sample(p trace.SamplingParameters) bool {
+	s.mu.RLock()
+	op, ok := s.operations[p.Name]
+	fallback := s.defaultProbability
+	var probability float64
+	var limiter *rateLimiter
+	if ok {
+		// Copy out of op while still holding the lock: update mutates
+		// op.probability in place under s.mu, concurrently with this read.
+		probability = op.probability
+		limiter = op.limiter
+	}
+	s.mu.RUnlock()
+
+	if !ok {
+		return sampleTraceID(p.TraceID, fallback)
+	}
+	if sampleTraceID(p.TraceID, probability) {
+		return true
+	}
+	return limiter.allow()
+}
+
+// update merges a freshly fetched strategy response into s, adding new
+// operations (up to maxOperations), updating known ones, and dropping
+// operations no longer returned by the sampling manager.
+func (s *perOperationStrategies) update(resp *sampling.SamplingStrategyResponse, maxOperations int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ops := resp.OperationSampling; ops != nil {
+		s.defaultProbability = ops.DefaultSamplingProbability
+		lowerBound := ops.DefaultLowerBoundTracesPerSecond
+
+		seen := make(map[string]bool, len(ops.PerOperationStrategies))
+		for _, strat := range ops.PerOperationStrategies {
+			if _, exists := s.operations[strat.Operation]; !exists && len(s.operations) >= maxOperations {
+				continue
+			}
+			seen[strat.Operation] = true
+			op, exists := s.operations[strat.Operation]
+			if !exists {
+				op = &operationStrategy{limiter: newRateLimiter(lowerBound, maxFloat(lowerBound, 1))}
+				s.operations[strat.Operation] = op
+			}
+			op.probability = strat.ProbabilisticSampling.GetSamplingRate()
+		}
+		for operation := range s.operations {
+			if !seen[operation] {
+				delete(s.operations, operation)
+			}
+		}
+	} else if resp.ProbabilisticSampling != nil {
+		s.defaultProbability = resp.ProbabilisticSampling.GetSamplingRate()
+	}
+}
+
+// sampleTraceID deterministically samples based on the trace ID, matching
+// the scheme used by trace.ProbabilitySampler so that a decision is
+// consistent across exporters given the same trace ID and probability.
+func sampleTraceID(id trace.TraceID, probability float64) bool {
+	if probability <= 0 {
+		return false
+	}
+	if probability >= 1 {
+		return true
+	}
+	bound := uint64(probability * (1 << 63))
+	x := binary.BigEndian.Uint64(id[0:8]) >> 1
+	return x < bound
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,169 @@
+// Copyright 2018, OpenCensus Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uber/jaeger-client-go/thrift-gen/sampling"
+	"go.opencensus.io/trace"
+)
+
+func strategyResponseServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("service"), "my-service"; got != want {
+			t.Errorf("service query param = %q, want %q", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestManager_perOperationStrategies(t *testing.T) {
+	srv := strategyResponseServer(t, `{
+		"strategyType": "PROBABILISTIC",
+		"operationSampling": {
+			"defaultSamplingProbability": 0,
+			"defaultLowerBoundTracesPerSecond": 0,
+			"perOperationStrategies": [
+				{"operation": "always", "probabilisticSampling": {"samplingRate": 1}},
+				{"operation": "never", "probabilisticSampling": {"samplingRate": 0}}
+			]
+		}
+	}`)
+
+	m, err := NewManager(Options{ServiceName: "my-service", SamplingServerURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	defer m.Close()
+
+	sampler := m.Sampler()
+	always := sampler(trace.SamplingParameters{Name: "always", TraceID: trace.TraceID{1}})
+	if !always.Sample {
+		t.Error("operation with samplingRate=1 was not sampled")
+	}
+	// The rate limiter backing "never" starts with one free credit (the
+	// same burst tolerance that guarantees throughput for low-volume
+	// operations), so the first call may be sampled via the limiter even
+	// though its probabilistic sampler never fires; drain that credit
+	// before asserting steady-state behavior.
+	sampler(trace.SamplingParameters{Name: "never", TraceID: trace.TraceID{1}})
+	never := sampler(trace.SamplingParameters{Name: "never", TraceID: trace.TraceID{1}})
+	if never.Sample {
+		t.Error("operation with samplingRate=0 was sampled")
+	}
+	unknown := sampler(trace.SamplingParameters{Name: "unknown", TraceID: trace.TraceID{1}})
+	if unknown.Sample {
+		t.Error("unknown operation fell back to sampling when defaultSamplingProbability=0")
+	}
+}
+
+func TestManager_rateLimiterGuaranteesThroughput(t *testing.T) {
+	srv := strategyResponseServer(t, `{
+		"strategyType": "PROBABILISTIC",
+		"operationSampling": {
+			"defaultSamplingProbability": 0,
+			"defaultLowerBoundTracesPerSecond": 1,
+			"perOperationStrategies": [
+				{"operation": "rare", "probabilisticSampling": {"samplingRate": 0}}
+			]
+		}
+	}`)
+
+	m, err := NewManager(Options{ServiceName: "my-service", SamplingServerURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	defer m.Close()
+
+	strategies := m.strategies.Load().(*perOperationStrategies)
+	limiter := strategies.operations["rare"].limiter
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	sampler := m.Sampler()
+	params := trace.SamplingParameters{Name: "rare", TraceID: trace.TraceID{1}}
+
+	if !sampler(params).Sample {
+		t.Error("first call should consume the initial rate-limiter balance and be sampled")
+	}
+	if sampler(params).Sample {
+		t.Error("second call before any time has elapsed should not be sampled")
+	}
+
+	now = now.Add(time.Second)
+	if !sampler(params).Sample {
+		t.Error("call after 1s at 1 credit/s should be sampled again")
+	}
+}
+
+func TestManager_requiresServiceName(t *testing.T) {
+	if _, err := NewManager(Options{}); err == nil {
+		t.Error("NewManager() error = nil, want error for missing ServiceName")
+	}
+}
+
+// TestPerOperationStrategies_concurrentSampleAndUpdate exercises sample and
+// update concurrently on the same *operationStrategy, so that `go test -race`
+// catches a regression of sample reading op.probability after releasing the
+// lock that update mutates it under.
+func TestPerOperationStrategies_concurrentSampleAndUpdate(t *testing.T) {
+	s := newPerOperationStrategies()
+	s.update(&sampling.SamplingStrategyResponse{
+		OperationSampling: &sampling.PerOperationSamplingStrategies{
+			PerOperationStrategies: []*sampling.OperationSamplingStrategy{
+				{Operation: "op", ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{SamplingRate: 1}},
+			},
+		},
+	}, defaultMaxOperations)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				s.sample(trace.SamplingParameters{Name: "op", TraceID: trace.TraceID{1}})
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			rate := float64(i % 2)
+			s.update(&sampling.SamplingStrategyResponse{
+				OperationSampling: &sampling.PerOperationSamplingStrategies{
+					PerOperationStrategies: []*sampling.OperationSamplingStrategy{
+						{Operation: "op", ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{SamplingRate: rate}},
+					},
+				},
+			}, defaultMaxOperations)
+		}
+		close(done)
+	}()
+	wg.Wait()
+}